@@ -0,0 +1,116 @@
+package phttp
+
+import (
+	"context"
+	"net/http"
+	"sync"
+
+	"golang.org/x/sync/semaphore"
+	"golang.org/x/time/rate"
+)
+
+// HostWaiter is a Waiter and RequestWaiter that enforces a rate limit per
+// request host, instead of the single global limit DefaultRateLimiter
+// applies regardless of which host a Client talks to. Limiters are created
+// lazily on first use and cached for the lifetime of the HostWaiter.
+type HostWaiter struct {
+	// Default is the rate.Limit used for hosts with no entry in Limits.
+	Default rate.Limit
+	// Limits overrides Default for specific hosts (req.URL.Host).
+	Limits map[string]rate.Limit
+	// Burst is the burst size passed to every per-host rate.Limiter.
+	Burst int
+
+	mu       sync.Mutex
+	limiters map[string]*rate.Limiter
+}
+
+// NewHostWaiter returns a HostWaiter using def as the default rate limit and
+// a burst of 1, with limits overriding def for specific hosts.
+func NewHostWaiter(limits map[string]rate.Limit, def rate.Limit) *HostWaiter {
+	return &HostWaiter{
+		Default: def,
+		Limits:  limits,
+		Burst:   1,
+	}
+}
+
+// Wait implements Waiter by waiting as if the request had no host, i.e.
+// under Default. Prefer WaitForRequest, which HostWaiter also implements,
+// so that per-host limits actually apply; do calls it automatically.
+func (w *HostWaiter) Wait(ctx context.Context) error {
+	return w.limiterFor("").Wait(ctx)
+}
+
+// WaitForRequest implements RequestWaiter, applying the rate limit
+// configured for req.URL.Host.
+func (w *HostWaiter) WaitForRequest(ctx context.Context, req *http.Request) error {
+	return w.limiterFor(req.URL.Host).Wait(ctx)
+}
+
+func (w *HostWaiter) limiterFor(host string) *rate.Limiter {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.limiters == nil {
+		w.limiters = make(map[string]*rate.Limiter)
+	}
+
+	if l, ok := w.limiters[host]; ok {
+		return l
+	}
+
+	limit := w.Default
+	if hostLimit, ok := w.Limits[host]; ok {
+		limit = hostLimit
+	}
+
+	l := rate.NewLimiter(limit, w.Burst)
+	w.limiters[host] = l
+
+	return l
+}
+
+// requesterFunc adapts a plain function to the Requester interface.
+type requesterFunc func(req *http.Request) (*http.Response, error)
+
+func (f requesterFunc) Do(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+// newHostConcurrencyLimiter returns a RequesterMiddleware that caps
+// in-flight requests to n per host (req.URL.Host), lazily creating a
+// semaphore.Weighted for each host it sees.
+func newHostConcurrencyLimiter(n int) RequesterMiddleware {
+	var (
+		mu   sync.Mutex
+		sems = make(map[string]*semaphore.Weighted)
+	)
+
+	semaphoreFor := func(host string) *semaphore.Weighted {
+		mu.Lock()
+		defer mu.Unlock()
+
+		if s, ok := sems[host]; ok {
+			return s
+		}
+
+		s := semaphore.NewWeighted(int64(n))
+		sems[host] = s
+
+		return s
+	}
+
+	return func(next Requester) Requester {
+		return requesterFunc(func(req *http.Request) (*http.Response, error) {
+			sem := semaphoreFor(req.URL.Host)
+
+			if err := sem.Acquire(req.Context(), 1); err != nil {
+				return nil, err
+			}
+			defer sem.Release(1)
+
+			return next.Do(req)
+		})
+	}
+}
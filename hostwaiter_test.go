@@ -0,0 +1,98 @@
+package phttp
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+
+	"golang.org/x/time/rate"
+)
+
+// TestHostWaiterIsolatesLimitsPerHost guards the entire reason
+// WithPerHostRateLimit exists over the old single global DefaultRateLimiter:
+// a low limit configured for one host must not throttle requests to
+// another.
+func TestHostWaiterIsolatesLimitsPerHost(t *testing.T) {
+	w := NewHostWaiter(map[string]rate.Limit{
+		"slow.example": rate.Every(time.Hour),
+	}, rate.Inf)
+
+	slow, err := http.NewRequest(http.MethodGet, "http://slow.example", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	fast, err := http.NewRequest(http.MethodGet, "http://fast.example", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Drain slow.example's single burst token so any further wait on it
+	// would block for about an hour.
+	if err := w.WaitForRequest(context.Background(), slow); err != nil {
+		t.Fatal(err)
+	}
+
+	shortCtx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	if err := w.WaitForRequest(shortCtx, slow); err == nil {
+		t.Fatal("expected a second wait on slow.example to be throttled")
+	}
+
+	if err := w.WaitForRequest(context.Background(), fast); err != nil {
+		t.Fatalf("expected fast.example to be unaffected by slow.example's limit, got %v", err)
+	}
+}
+
+// fakeWaiter implements both Waiter and RequestWaiter so its calls record
+// which method a caller chose.
+type fakeWaiter struct {
+	mu              sync.Mutex
+	waitCalls       int
+	waitForReqCalls int
+}
+
+func (f *fakeWaiter) Wait(ctx context.Context) error {
+	f.mu.Lock()
+	f.waitCalls++
+	f.mu.Unlock()
+
+	return nil
+}
+
+func (f *fakeWaiter) WaitForRequest(ctx context.Context, req *http.Request) error {
+	f.mu.Lock()
+	f.waitForReqCalls++
+	f.mu.Unlock()
+
+	return nil
+}
+
+// TestDoPrefersWaitForRequestOverWait guards do()'s documented preference
+// for RequestWaiter over the plain Waiter interface when a configured
+// Waiter implements both.
+func TestDoPrefersWaitForRequestOverWait(t *testing.T) {
+	fw := &fakeWaiter{}
+
+	base := requesterFunc(func(req *http.Request) (*http.Response, error) {
+		return &http.Response{StatusCode: http.StatusOK, Body: http.NoBody}, nil
+	})
+
+	c := New(WithHttpClient(base), WithRateLimiter(fw))
+
+	req, err := http.NewRequest(http.MethodGet, "http://example.invalid", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := c.Do(req); err != nil {
+		t.Fatal(err)
+	}
+
+	if fw.waitForReqCalls != 1 || fw.waitCalls != 0 {
+		t.Fatalf("expected WaitForRequest to be called once and Wait never, got WaitForRequest=%d Wait=%d", fw.waitForReqCalls, fw.waitCalls)
+	}
+}
@@ -1,10 +1,18 @@
 package phttp
 
 import (
+	"bytes"
 	"context"
+	"crypto/tls"
+	"errors"
 	"fmt"
 	"io"
+	"math"
+	"math/rand"
 	"net/http"
+	"strconv"
+	"strings"
+	"sync"
 	"time"
 
 	"github.com/cenkalti/backoff/v4"
@@ -33,6 +41,22 @@ type Client struct {
 	Requester Requester
 	Waiter    Waiter
 	Backoff   backoff.BackOff
+
+	// CheckRetry decides whether an attempt should be retried, given the
+	// response and/or error from that attempt. If nil, DefaultCheckRetry is
+	// used: retry on network errors and 429/5xx responses, fail permanently
+	// on other 4xx. Returning a non-nil error short-circuits retrying entirely and
+	// that error is returned to the caller.
+	CheckRetry func(ctx context.Context, resp *http.Response, err error) (bool, error)
+
+	// MaxRetryAfter caps how long Do will sleep to honor a server-provided
+	// Retry-After header. A zero value means no cap.
+	MaxRetryAfter time.Duration
+
+	// Hedging, if set, fires concurrent staggered attempts for idempotent
+	// requests (GET/HEAD/OPTIONS) and returns the first successful
+	// response. See WithHedging.
+	Hedging *HedgingConfig
 }
 
 // Requester is this libraries interface for a http.Client.
@@ -47,6 +71,14 @@ type Waiter interface {
 	Wait(ctx context.Context) error
 }
 
+// RequestWaiter is an optional extension of Waiter that lets the wait
+// decision depend on the outgoing request, e.g. to apply a per-host rate
+// limit. do prefers it over Waiter when a configured Waiter implements it,
+// so existing Waiter implementations keep working unchanged.
+type RequestWaiter interface {
+	WaitForRequest(ctx context.Context, req *http.Request) error
+}
+
 // Option is a function to alter the behaviour of a Client.
 type Option func(c *Client)
 
@@ -72,6 +104,109 @@ func WithBackOff(bo backoff.BackOff) Option {
 	}
 }
 
+// WithCheckRetry configures the Client to use the given retry policy instead
+// of DefaultCheckRetry.
+func WithCheckRetry(checkRetry func(ctx context.Context, resp *http.Response, err error) (bool, error)) Option {
+	return func(c *Client) {
+		c.CheckRetry = checkRetry
+	}
+}
+
+// WithMaxRetryAfter caps how long Do will sleep to honor a server-provided
+// Retry-After header.
+func WithMaxRetryAfter(d time.Duration) Option {
+	return func(c *Client) {
+		c.MaxRetryAfter = d
+	}
+}
+
+// RequesterMiddleware wraps a Requester with additional behaviour, such as
+// logging, tracing, metrics, or circuit breaking. Because it wraps Requester
+// rather than Client.Do, a middleware sees every individual attempt made by
+// the retry loop, not just the final result returned to the caller.
+type RequesterMiddleware func(Requester) Requester
+
+// attemptContextKey is the context key do() uses to record which attempt,
+// of a single logical Do/DoRequest call, is currently in flight.
+type attemptContextKey struct{}
+
+// withAttempt returns a shallow copy of req carrying n as its attempt
+// number, retrievable by a RequesterMiddleware via AttemptFromContext. Each
+// top-level Do/DoRequest call - and each concurrent hedge attempt - keeps
+// its own count, so this never grows across unrelated requests sharing one
+// Client and never needs a mutex.
+func withAttempt(req *http.Request, n int) *http.Request {
+	return req.WithContext(context.WithValue(req.Context(), attemptContextKey{}, n))
+}
+
+// AttemptFromContext returns the 0-based attempt number of the request
+// currently flowing through a Client's Requester chain, as set by
+// Client.Do/DoRequest. ok is false if req wasn't issued through a Client.
+func AttemptFromContext(ctx context.Context) (int, bool) {
+	n, ok := ctx.Value(attemptContextKey{}).(int)
+	return n, ok
+}
+
+// WithMiddleware wraps the Client's current Requester with the given
+// middlewares. They compose in order: the first middleware is outermost and
+// sees every attempt first. Options run in order, so WithMiddleware should
+// come after WithHttpClient if both are used.
+func WithMiddleware(mws ...RequesterMiddleware) Option {
+	return func(c *Client) {
+		for i := len(mws) - 1; i >= 0; i-- {
+			c.Requester = mws[i](c.Requester)
+		}
+	}
+}
+
+// WithPerHostRateLimit configures the Client with a HostWaiter: limits
+// overrides the rate limit for specific hosts (req.URL.Host), and def is
+// used for any host without an entry in limits.
+func WithPerHostRateLimit(limits map[string]rate.Limit, def rate.Limit) Option {
+	return func(c *Client) {
+		c.Waiter = NewHostWaiter(limits, def)
+	}
+}
+
+// WithPerHostConcurrency wraps the Client's current Requester so that no
+// more than n requests to the same host (req.URL.Host) are in flight at
+// once. Options run in order, so WithPerHostConcurrency should come after
+// WithHttpClient if both are used.
+func WithPerHostConcurrency(n int) Option {
+	return func(c *Client) {
+		c.Requester = newHostConcurrencyLimiter(n)(c.Requester)
+	}
+}
+
+// HedgingConfig configures concurrent "hedged" requests. See WithHedging.
+type HedgingConfig struct {
+	// N is the maximum number of parallel attempts.
+	N int
+	// Delay staggers attempt i by i*Delay before it fires.
+	Delay time.Duration
+}
+
+// hedgeableMethods lists the methods WithHedging is allowed to hedge;
+// firing the same non-idempotent request twice could duplicate side effects.
+var hedgeableMethods = map[string]struct{}{
+	http.MethodGet:     {},
+	http.MethodHead:    {},
+	http.MethodOptions: {},
+}
+
+// WithHedging configures the Client to fire up to n parallel attempts for
+// idempotent requests (GET/HEAD/OPTIONS), staggered by delay, returning the
+// first successful response and canceling the rest. Each attempt still goes
+// through the full retry and rate-limiting path, so hedging composes with
+// WithBackOff and WithRateLimiter/WithPerHostRateLimit. Requests with any
+// other method always use the plain, unhedged retry path. Only Do hedges;
+// DoRequest never does, whatever the method.
+func WithHedging(n int, delay time.Duration) Option {
+	return func(c *Client) {
+		c.Hedging = &HedgingConfig{N: n, Delay: delay}
+	}
+}
+
 // NewWithDefaults returns a client with the two default options for rate
 // limiting and backoff DefaultRateLimiter and DefaultBackOff
 func NewWithDefaults() *Client {
@@ -93,26 +228,384 @@ func New(opts ...Option) *Client {
 	return client
 }
 
+// DefaultCheckRetry is the retry policy used when Client.CheckRetry is nil:
+// retry on network errors and on 429/5xx responses, fail permanently on
+// other 4xx responses.
+func DefaultCheckRetry(ctx context.Context, resp *http.Response, err error) (bool, error) {
+	if ctx.Err() != nil {
+		return false, ctx.Err()
+	}
+
+	if err != nil {
+		return IsTemporary(err), nil
+	}
+
+	if resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500 {
+		return true, nil
+	}
+
+	return false, nil
+}
+
+// BackOffCloner lets a custom backoff.BackOff supply an independent copy of
+// its own state. cloneBackOff uses it so a custom BackOff, like the two
+// this package ships, can be shared as Client.Backoff yet still give every
+// concurrent Do/DoRequest call - and every concurrent hedge attempt - its
+// own mutable state instead of racing on the Client's configured instance.
+type BackOffCloner interface {
+	CloneBackOff() backoff.BackOff
+}
+
+// cloneBackOff returns an independent copy of bo. backoff.ExponentialBackOff
+// and FullJitterBackOff, the two stateful backoffs this package deals in,
+// are cloned directly by value; anything implementing BackOffCloner is
+// cloned through that; anything else is returned as-is and must already be
+// safe for concurrent use, since it will be shared across every in-flight
+// Do/DoRequest call.
+func cloneBackOff(bo backoff.BackOff) backoff.BackOff {
+	switch b := bo.(type) {
+	case *backoff.ExponentialBackOff:
+		clone := *b
+		return &clone
+	case *FullJitterBackOff:
+		clone := *b
+		return &clone
+	case BackOffCloner:
+		return b.CloneBackOff()
+	default:
+		return bo
+	}
+}
+
+// retryAfterBackOff wraps a backoff.BackOff and lets do() force a minimum
+// delay for the next NextBackOff call, so a server-provided Retry-After
+// value is never undercut by the wrapped backoff's own schedule.
+type retryAfterBackOff struct {
+	backoff.BackOff
+
+	mu    sync.Mutex
+	floor time.Duration
+}
+
+func (b *retryAfterBackOff) NextBackOff() time.Duration {
+	next := b.BackOff.NextBackOff()
+
+	b.mu.Lock()
+	floor := b.floor
+	b.floor = 0
+	b.mu.Unlock()
+
+	if next == backoff.Stop {
+		return backoff.Stop
+	}
+
+	if floor > next {
+		return floor
+	}
+
+	return next
+}
+
+func (b *retryAfterBackOff) setFloor(d time.Duration) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.floor = d
+}
+
+// FullJitterBackOff implements backoff.BackOff using the "full jitter"
+// strategy (delay = rand(0, min(Cap, Base*2^attempt))) described in
+// https://aws.amazon.com/blogs/architecture/exponential-backoff-and-jitter/.
+// Compared to DefaultBackOff's randomized exponential backoff, full jitter
+// spreads retries out more aggressively, which helps avoid a thundering
+// herd of clients retrying a recovering service in lockstep.
+type FullJitterBackOff struct {
+	// Base is the upper bound of the first delay; the bound doubles with
+	// every further attempt, up to Cap.
+	Base time.Duration
+	// Cap is the maximum delay, regardless of attempt count.
+	Cap time.Duration
+	// MaxAttempts stops retrying once exceeded. Zero means unlimited.
+	MaxAttempts int
+
+	attempt int
+}
+
+// NextBackOff returns the next delay, or backoff.Stop once MaxAttempts is exceeded.
+func (b *FullJitterBackOff) NextBackOff() time.Duration {
+	if b.MaxAttempts > 0 && b.attempt >= b.MaxAttempts {
+		return backoff.Stop
+	}
+
+	capped := math.Min(float64(b.Cap), float64(b.Base)*math.Pow(2, float64(b.attempt)))
+	b.attempt++
+
+	return time.Duration(rand.Int63n(int64(capped) + 1))
+}
+
+// Reset resets the attempt counter to zero.
+func (b *FullJitterBackOff) Reset() {
+	b.attempt = 0
+}
+
+// Request wraps an *http.Request together with whatever is needed to
+// recreate its body before every retry attempt. Build one with NewRequest
+// and send it with Client.DoRequest.
+type Request struct {
+	*http.Request
+
+	body func() (io.ReadCloser, error)
+}
+
+// NewRequest builds a Request whose body can be safely replayed across
+// retries. body may be nil, an io.Reader, an io.ReadSeeker, []byte, string,
+// or a func() (io.ReadCloser, error) factory that produces a fresh body on
+// every call; anything else is rejected. Only io.ReadSeeker and the
+// concrete/factory forms can actually be rewound - a plain io.Reader is used
+// as-is and will send an empty body on retry, same as the plain Do path.
+func NewRequest(ctx context.Context, method, url string, body interface{}) (*Request, error) {
+	factory, contentLength, err := newBodyFactory(body)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, url, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	if factory != nil {
+		rc, err := factory()
+		if err != nil {
+			return nil, err
+		}
+
+		req.Body = rc
+		req.ContentLength = contentLength
+	}
+
+	return &Request{Request: req, body: factory}, nil
+}
+
+// rewind recreates the request body, if it knows how, so it can be resent on
+// the next retry attempt.
+func (r *Request) rewind() error {
+	if r.body == nil {
+		return nil
+	}
+
+	rc, err := r.body()
+	if err != nil {
+		return err
+	}
+
+	r.Request.Body = rc
+
+	return nil
+}
+
+// seekerLen returns rs's total length by seeking to its end and back to
+// start, so NewRequest can set a known Content-Length instead of forcing
+// chunked transfer encoding on every retry.
+func seekerLen(rs io.ReadSeeker) (int64, error) {
+	length, err := rs.Seek(0, io.SeekEnd)
+	if err != nil {
+		return -1, err
+	}
+
+	if _, err := rs.Seek(0, io.SeekStart); err != nil {
+		return -1, err
+	}
+
+	return length, nil
+}
+
+func newBodyFactory(body interface{}) (func() (io.ReadCloser, error), int64, error) {
+	switch b := body.(type) {
+	case nil:
+		return nil, 0, nil
+	case func() (io.ReadCloser, error):
+		return b, -1, nil
+	case []byte:
+		return func() (io.ReadCloser, error) {
+			return io.NopCloser(bytes.NewReader(b)), nil
+		}, int64(len(b)), nil
+	case string:
+		return func() (io.ReadCloser, error) {
+			return io.NopCloser(strings.NewReader(b)), nil
+		}, int64(len(b)), nil
+	case io.ReadSeeker:
+		length, err := seekerLen(b)
+		if err != nil {
+			return nil, 0, err
+		}
+
+		return func() (io.ReadCloser, error) {
+			if _, err := b.Seek(0, io.SeekStart); err != nil {
+				return nil, err
+			}
+
+			return io.NopCloser(b), nil
+		}, length, nil
+	case io.Reader:
+		return func() (io.ReadCloser, error) {
+			return io.NopCloser(b), nil
+		}, -1, nil
+	default:
+		return nil, 0, fmt.Errorf("phttp: unsupported body type %T", body)
+	}
+}
+
 // Do is the interface for http.Client.Do.
 func (c *Client) Do(req *http.Request) (*http.Response, error) {
+	if c.Hedging != nil && c.Hedging.N > 1 {
+		if _, ok := hedgeableMethods[req.Method]; ok {
+			return c.doHedged(req)
+		}
+	}
+
+	return c.retry(req, nil)
+}
+
+// hedgeResult is one hedged attempt's outcome, funneled back to doHedged.
+type hedgeResult struct {
+	resp *http.Response
+	err  error
+}
+
+// doHedged fires up to c.Hedging.N attempts against req, staggered by
+// c.Hedging.Delay, and returns the first successful response. Every other
+// attempt's context is canceled once a winner is found, and any response
+// body it already received is drained and closed so the connection can be
+// reused.
+func (c *Client) doHedged(req *http.Request) (*http.Response, error) {
+	ctx, cancel := context.WithCancel(req.Context())
+	defer cancel()
+
+	results := make(chan hedgeResult, c.Hedging.N)
+
+	var wg sync.WaitGroup
+
+	for i := 0; i < c.Hedging.N; i++ {
+		i := i
+
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			if i > 0 {
+				timer := time.NewTimer(time.Duration(i) * c.Hedging.Delay)
+				defer timer.Stop()
+
+				select {
+				case <-ctx.Done():
+					return
+				case <-timer.C:
+				}
+			}
+
+			resp, err := c.retry(req.Clone(ctx), nil)
+
+			select {
+			case results <- hedgeResult{resp: resp, err: err}:
+			case <-ctx.Done():
+				if resp != nil {
+					io.Copy(io.Discard, resp.Body) //nolint:errcheck
+					resp.Body.Close()
+				}
+			}
+		}()
+	}
+
+	go func() {
+		wg.Wait()
+		close(results)
+	}()
+
+	var lastErr error
+
+	for res := range results {
+		if res.err != nil {
+			lastErr = res.err
+			continue
+		}
+
+		cancel()
+
+		go drainHedgeResults(results)
+
+		return res.resp, nil
+	}
+
+	if lastErr == nil {
+		lastErr = errors.New("phttp: all hedged attempts failed")
+	}
+
+	return nil, lastErr
+}
+
+// drainHedgeResults closes out any hedged attempts still in flight after a
+// winner has already been returned, so their connections can be reused.
+func drainHedgeResults(results <-chan hedgeResult) {
+	for res := range results {
+		if res.resp != nil {
+			io.Copy(io.Discard, res.resp.Body) //nolint:errcheck
+			res.resp.Body.Close()
+		}
+	}
+}
+
+// DoRequest performs req, rewinding or recreating its body before every
+// retry attempt. Use this instead of Do whenever req carries a body that a
+// failed attempt may have already consumed.
+func (c *Client) DoRequest(req *Request) (*http.Response, error) {
+	return c.retry(req.Request, req.rewind)
+}
+
+// retry drives the backoff.Retry loop shared by Do and DoRequest. rewind is
+// called before every attempt but the first; it is nil for plain Do calls.
+func (c *Client) retry(req *http.Request, rewind func() error) (*http.Response, error) {
 	if c.Backoff == nil {
-		return c.do(req)
+		resp, _, err := c.do(withAttempt(req, 0))
+		return resp, err
 	}
 
+	bo := &retryAfterBackOff{BackOff: cloneBackOff(c.Backoff)}
+
 	var resp *http.Response
 
+	first := true
+	attempt := 0
+
 	operation := func() error {
-		var err error
+		if !first && rewind != nil {
+			if err := rewind(); err != nil {
+				return backoff.Permanent(err)
+			}
+		}
+		first = false
+
+		var (
+			retryAfter time.Duration
+			err        error
+		)
+
+		resp, retryAfter, err = c.do(withAttempt(req, attempt))
+		attempt++
 
-		resp, err = c.do(req)
 		if err != nil {
+			if retryAfter > 0 {
+				bo.setFloor(capRetryAfter(retryAfter, c.MaxRetryAfter))
+			}
+
 			return err
 		}
 
 		return nil
 	}
 
-	err := backoff.Retry(operation, c.Backoff)
+	err := backoff.Retry(operation, bo)
 	if err != nil {
 		return nil, fmt.Errorf("exhausted all retries: %w", err)
 	}
@@ -120,6 +613,18 @@ func (c *Client) Do(req *http.Request) (*http.Response, error) {
 	return resp, nil
 }
 
+// Error is implemented by the typed errors Client.do builds for a failed
+// attempt (NetworkError, TLSCertError, HTTPError, RateLimitError), so
+// callers can use errors.As to branch on a specific failure kind instead of
+// string-matching the error text. Errors from the Waiter or from CheckRetry
+// are passed on as they are and need not implement Error.
+type Error interface {
+	error
+	// Temporary reports whether retrying the attempt might succeed.
+	Temporary() bool
+	Unwrap() error
+}
+
 // HTTPError exposes the http.Response while also giving some convenience for the http status code & response body.
 type HTTPError struct {
 	Code     int
@@ -136,43 +641,218 @@ func (e HTTPError) Error() string {
 	return fmt.Sprintf("failed HTTP call: %d", e.Code)
 }
 
-func (c *Client) do(req *http.Request) (*http.Response, error) {
+// Temporary reports whether e's status code is one DefaultCheckRetry would retry.
+func (e HTTPError) Temporary() bool {
+	return e.Code == http.StatusTooManyRequests || e.Code >= 500
+}
+
+// Unwrap always returns nil: HTTPError is built directly from the response,
+// not from a wrapped error.
+func (e HTTPError) Unwrap() error {
+	return nil
+}
+
+// RateLimitError is a 429 response, with the parsed Retry-After header
+// surfaced as RetryAfter so callers don't have to parse it themselves.
+type RateLimitError struct {
+	HTTPError
+
+	RetryAfter time.Duration
+}
+
+// Temporary always returns true: a 429 is the canonical retryable response.
+func (e RateLimitError) Temporary() bool {
+	return true
+}
+
+// NetworkError wraps a transport-level failure (dial, timeout, connection
+// reset, ...) returned by the Requester before any HTTP response arrived.
+type NetworkError struct {
+	Err error
+}
+
+// Error prefixes the wrapped error so it's distinguishable from an HTTPError in logs.
+func (e NetworkError) Error() string {
+	return fmt.Sprintf("network error: %s", e.Err)
+}
+
+func (e NetworkError) Unwrap() error {
+	return e.Err
+}
+
+// Temporary always returns true: network errors are assumed transient,
+// matching the pre-existing "retry network errors" behaviour.
+func (e NetworkError) Temporary() bool {
+	return true
+}
+
+// TLSCertError wraps a certificate verification failure. It is always
+// permanent: retrying won't change whether a certificate is trusted. This
+// mirrors go-retryablehttp's handling of *tls.CertificateVerificationError.
+type TLSCertError struct {
+	Err error
+}
+
+func (e TLSCertError) Error() string {
+	return fmt.Sprintf("certificate verification failed: %s", e.Err)
+}
+
+func (e TLSCertError) Unwrap() error {
+	return e.Err
+}
+
+// Temporary always returns false.
+func (e TLSCertError) Temporary() bool {
+	return false
+}
+
+// IsTemporary reports whether err is a phttp.Error considered worth
+// retrying. An err that doesn't implement phttp.Error is treated as
+// temporary, matching the library's long-standing "retry network errors"
+// default.
+func IsTemporary(err error) bool {
+	var perr Error
+	if errors.As(err, &perr) {
+		return perr.Temporary()
+	}
+
+	return true
+}
+
+// IsRateLimited reports whether err is a RateLimitError, returning the
+// Retry-After duration the server asked for.
+func IsRateLimited(err error) (time.Duration, bool) {
+	var rle RateLimitError
+	if errors.As(err, &rle) {
+		return rle.RetryAfter, true
+	}
+
+	return 0, false
+}
+
+// classifyError turns a transport-level error from the Requester into a
+// typed phttp.Error.
+func classifyError(err error) error {
+	var certErr *tls.CertificateVerificationError
+	if errors.As(err, &certErr) {
+		return TLSCertError{Err: err}
+	}
+
+	return NetworkError{Err: err}
+}
+
+// newRetryableError builds the typed error for a non-success response,
+// whether or not do() retries it: a RateLimitError for 429s, surfacing
+// retryAfter, or a plain HTTPError otherwise.
+func newRetryableError(code int, body string, resp *http.Response, retryAfter time.Duration) error {
+	httpErr := HTTPError{Code: code, Body: body, Response: resp}
+
+	if code == http.StatusTooManyRequests {
+		return RateLimitError{HTTPError: httpErr, RetryAfter: retryAfter}
+	}
+
+	return httpErr
+}
+
+// do performs a single attempt and additionally reports the Retry-After
+// delay the server asked for, if any, so Do can feed it into the backoff.
+func (c *Client) do(req *http.Request) (*http.Response, time.Duration, error) {
 	if c.Waiter != nil {
-		err := c.Waiter.Wait(req.Context())
+		var err error
+
+		if rw, ok := c.Waiter.(RequestWaiter); ok {
+			err = rw.WaitForRequest(req.Context(), req)
+		} else {
+			err = c.Waiter.Wait(req.Context())
+		}
+
 		if err != nil {
-			return nil, err
+			return nil, 0, err
 		}
 	}
 
 	resp, err := c.Requester.Do(req)
 	if err != nil {
-		return nil, err
+		err = classifyError(err)
 	}
 
-	if resp.StatusCode > 399 && resp.StatusCode < 500 {
-		bodyBytes, err := readHTTPBody(resp.Body)
-		if err != nil {
-			return nil, backoff.Permanent(HTTPError{
-				Code:     resp.StatusCode,
-				Response: resp,
-			})
+	checkRetry := c.CheckRetry
+	if checkRetry == nil {
+		checkRetry = DefaultCheckRetry
+	}
+
+	retry, checkErr := checkRetry(req.Context(), resp, err)
+	if checkErr != nil {
+		return nil, 0, backoff.Permanent(checkErr)
+	}
+
+	if err != nil {
+		if retry {
+			return nil, 0, err
 		}
 
-		return nil, backoff.Permanent(HTTPError{
-			Code:     resp.StatusCode,
-			Body:     string(bodyBytes),
-			Response: resp,
-		})
+		return nil, 0, backoff.Permanent(err)
 	}
 
-	//TODO: Add Retry-After parsing if it's existing
-	// Implementation might be assuming worst case
-	// that the retry is the minimal of c.EBackoff
-	// so we sleep for Retry-After - c.EBackoff.InitialInterval
-	// that should put us either right on or slightly above the
-	// desired value of the system.
-	// Max wait time would be Retry-After + c.EBackoff.MaxInterval
-	return resp, nil
+	if !retry {
+		if resp.StatusCode < http.StatusOK || resp.StatusCode >= http.StatusBadRequest {
+			bodyBytes, bodyErr := readHTTPBody(resp.Body)
+			if bodyErr != nil {
+				return nil, 0, backoff.Permanent(newRetryableError(resp.StatusCode, "", resp, 0))
+			}
+
+			return nil, 0, backoff.Permanent(newRetryableError(resp.StatusCode, string(bodyBytes), resp, 0))
+		}
+
+		return resp, 0, nil
+	}
+
+	retryAfter, _ := parseRetryAfter(resp.Header.Get("Retry-After"))
+
+	bodyBytes, bodyErr := readHTTPBody(resp.Body)
+	if bodyErr != nil {
+		return nil, retryAfter, newRetryableError(resp.StatusCode, "", resp, retryAfter)
+	}
+
+	return nil, retryAfter, newRetryableError(resp.StatusCode, string(bodyBytes), resp, retryAfter)
+}
+
+// parseRetryAfter parses a Retry-After header value as either delta-seconds
+// or an HTTP-date, per RFC 7231 section 7.1.3. The bool return reports
+// whether a valid, non-negative delay was found.
+func parseRetryAfter(value string) (time.Duration, bool) {
+	if value == "" {
+		return 0, false
+	}
+
+	if seconds, err := strconv.Atoi(value); err == nil {
+		if seconds < 0 {
+			return 0, false
+		}
+
+		return time.Duration(seconds) * time.Second, true
+	}
+
+	t, err := http.ParseTime(value)
+	if err != nil {
+		return 0, false
+	}
+
+	d := time.Until(t)
+	if d < 0 {
+		return 0, false
+	}
+
+	return d, true
+}
+
+// capRetryAfter clamps d to max, unless max is zero in which case d is left uncapped.
+func capRetryAfter(d, max time.Duration) time.Duration {
+	if max > 0 && d > max {
+		return max
+	}
+
+	return d
 }
 
 func readHTTPBody(bodyReader io.ReadCloser) ([]byte, error) {
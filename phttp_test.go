@@ -0,0 +1,280 @@
+package phttp
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/cenkalti/backoff/v4"
+)
+
+func TestDoHonorsCheckRetryDecliningNonSuccessStatus(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer srv.Close()
+
+	c := New(WithCheckRetry(func(ctx context.Context, resp *http.Response, err error) (bool, error) {
+		return false, nil
+	}))
+
+	req, err := http.NewRequest(http.MethodGet, srv.URL, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	resp, err := c.Do(req)
+	if err == nil {
+		t.Fatalf("expected an error for a 503 that CheckRetry declined to retry, got resp %v", resp)
+	}
+
+	var httpErr HTTPError
+	if !errors.As(err, &httpErr) {
+		t.Fatalf("expected an HTTPError, got %T: %v", err, err)
+	}
+
+	if httpErr.Code != http.StatusServiceUnavailable {
+		t.Fatalf("expected status %d, got %d", http.StatusServiceUnavailable, httpErr.Code)
+	}
+}
+
+func TestNewRequestCapturesReadSeekerContentLength(t *testing.T) {
+	body := bytes.NewReader([]byte("hello world"))
+
+	req, err := NewRequest(context.Background(), http.MethodPut, "http://example.invalid", body)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if req.ContentLength != int64(len("hello world")) {
+		t.Fatalf("expected ContentLength %d, got %d", len("hello world"), req.ContentLength)
+	}
+}
+
+// TestDoRequestResendsFullBodyOnRetry guards against a retried request
+// silently sending an empty or truncated body: the server below serves a
+// 503 then a 200, and fails the test unless it reads the full body on both
+// attempts.
+func TestDoRequestResendsFullBodyOnRetry(t *testing.T) {
+	const want = "hello world"
+
+	cases := []struct {
+		name string
+		body interface{}
+	}{
+		{"bytes", []byte(want)},
+		{"string", want},
+		{"readSeeker", bytes.NewReader([]byte(want))},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			var got []string
+
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				b, err := io.ReadAll(r.Body)
+				if err != nil {
+					t.Fatal(err)
+				}
+				got = append(got, string(b))
+
+				if len(got) == 1 {
+					w.WriteHeader(http.StatusServiceUnavailable)
+					return
+				}
+
+				w.WriteHeader(http.StatusOK)
+			}))
+			defer srv.Close()
+
+			c := New(WithBackOff(&backoff.ZeroBackOff{}))
+
+			req, err := NewRequest(context.Background(), http.MethodPost, srv.URL, tc.body)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if _, err := c.DoRequest(req); err != nil {
+				t.Fatal(err)
+			}
+
+			if want := []string{want, want}; !equalStrings(got, want) {
+				t.Fatalf("expected the server to see the full body on both attempts, got %v", got)
+			}
+		})
+	}
+}
+
+func equalStrings(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+
+	return true
+}
+
+// TestAttemptFromContextResetsPerDoCall guards against a middleware deriving
+// the attempt number from state shared across every request a Client ever
+// makes: it must count up within one Do call and reset on the next.
+func TestAttemptFromContextResetsPerDoCall(t *testing.T) {
+	var attempts []int
+
+	recordAttempt := func(next Requester) Requester {
+		return requesterFunc(func(req *http.Request) (*http.Response, error) {
+			n, _ := AttemptFromContext(req.Context())
+			attempts = append(attempts, n)
+
+			return next.Do(req)
+		})
+	}
+
+	var calls int
+
+	base := requesterFunc(func(req *http.Request) (*http.Response, error) {
+		calls++
+		if calls < 3 {
+			return &http.Response{StatusCode: http.StatusServiceUnavailable, Body: http.NoBody}, nil
+		}
+
+		return &http.Response{StatusCode: http.StatusOK, Body: http.NoBody}, nil
+	})
+
+	c := New(
+		WithHttpClient(base),
+		WithMiddleware(recordAttempt),
+		WithBackOff(&backoff.ZeroBackOff{}),
+	)
+
+	req, err := http.NewRequest(http.MethodGet, "http://example.invalid", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := c.Do(req); err != nil {
+		t.Fatal(err)
+	}
+
+	if got := []int{0, 1, 2}; !equalInts(attempts, got) {
+		t.Fatalf("expected attempts %v for the first Do call, got %v", got, attempts)
+	}
+
+	attempts = nil
+	calls = 0
+
+	if _, err := c.Do(req); err != nil {
+		t.Fatal(err)
+	}
+
+	if got := []int{0, 1, 2}; !equalInts(attempts, got) {
+		t.Fatalf("expected attempt count to reset on a second Do call, got %v", attempts)
+	}
+}
+
+func equalInts(a, b []int) bool {
+	if len(a) != len(b) {
+		return false
+	}
+
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+
+	return true
+}
+
+// TestHedgingRespectsMaxElapsedTime guards against hedged attempts sharing
+// one mutable backoff.BackOff: if they did, concurrently resetting its
+// clock would defeat MaxElapsedTime and the always-failing request below
+// would keep retrying well past it.
+func TestHedgingRespectsMaxElapsedTime(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer srv.Close()
+
+	bo := backoff.ExponentialBackOff{
+		InitialInterval:     10 * time.Millisecond,
+		RandomizationFactor: 0,
+		Multiplier:          2,
+		MaxInterval:         50 * time.Millisecond,
+		MaxElapsedTime:      150 * time.Millisecond,
+		Stop:                backoff.Stop,
+		Clock:               backoff.SystemClock,
+	}
+
+	c := New(WithBackOff(&bo), WithHedging(4, 5*time.Millisecond))
+
+	req, err := http.NewRequest(http.MethodGet, srv.URL, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	start := time.Now()
+
+	if _, err := c.Do(req); err == nil {
+		t.Fatal("expected an error from an always-500 server")
+	}
+
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Fatalf("expected MaxElapsedTime (150ms) to bound hedged retries, took %s", elapsed)
+	}
+}
+
+// TestConcurrentDoDoesNotShareBackOffState exercises many concurrent Do
+// calls against an always-failing server on one Client. Run with -race: a
+// shared, non-thread-safe backoff.BackOff (e.g. the default
+// backoff.ExponentialBackOff) would be flagged here.
+func TestConcurrentDoDoesNotShareBackOffState(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer srv.Close()
+
+	bo := backoff.ExponentialBackOff{
+		InitialInterval:     time.Millisecond,
+		RandomizationFactor: 0,
+		Multiplier:          2,
+		MaxInterval:         5 * time.Millisecond,
+		MaxElapsedTime:      50 * time.Millisecond,
+		Stop:                backoff.Stop,
+		Clock:               backoff.SystemClock,
+	}
+
+	c := New(WithBackOff(&bo))
+
+	var wg sync.WaitGroup
+
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			req, err := http.NewRequest(http.MethodGet, srv.URL, nil)
+			if err != nil {
+				t.Error(err)
+				return
+			}
+
+			if _, err := c.Do(req); err == nil {
+				t.Error("expected an error from an always-500 server")
+			}
+		}()
+	}
+
+	wg.Wait()
+}
@@ -0,0 +1,103 @@
+package phttpmw
+
+import (
+	"errors"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/Deichindianer/phttp"
+	"github.com/cenkalti/backoff/v4"
+)
+
+// ErrCircuitOpen is returned when CircuitBreaker has tripped and is
+// rejecting attempts until its OpenDuration elapses.
+var ErrCircuitOpen = errors.New("phttpmw: circuit breaker open")
+
+// CircuitBreakerConfig configures CircuitBreaker.
+type CircuitBreakerConfig struct {
+	// Window is the rolling window over which the error rate is computed.
+	Window time.Duration
+	// MinRequests is the minimum number of attempts within Window before
+	// the error rate is evaluated; below this, the breaker stays closed.
+	MinRequests int
+	// ErrorRateThreshold opens the breaker once the error rate over Window
+	// exceeds this fraction (0..1).
+	ErrorRateThreshold float64
+	// OpenDuration is how long the breaker rejects attempts once open.
+	OpenDuration time.Duration
+}
+
+// CircuitBreaker returns a RequesterMiddleware that tracks a rolling window
+// of attempt outcomes and, once the error rate crosses cfg.ErrorRateThreshold,
+// short-circuits with backoff.Permanent(ErrCircuitOpen) instead of letting
+// the backoff loop keep hammering a downstream that is already down.
+func CircuitBreaker(cfg CircuitBreakerConfig) phttp.RequesterMiddleware {
+	return func(next phttp.Requester) phttp.Requester {
+		return &circuitBreaker{cfg: cfg, next: next}
+	}
+}
+
+type outcome struct {
+	at      time.Time
+	failure bool
+}
+
+type circuitBreaker struct {
+	cfg  CircuitBreakerConfig
+	next phttp.Requester
+
+	mu        sync.Mutex
+	history   []outcome
+	openUntil time.Time
+}
+
+func (cb *circuitBreaker) Do(req *http.Request) (*http.Response, error) {
+	cb.mu.Lock()
+	open := time.Now().Before(cb.openUntil)
+	cb.mu.Unlock()
+
+	if open {
+		return nil, backoff.Permanent(ErrCircuitOpen)
+	}
+
+	resp, err := cb.next.Do(req)
+
+	cb.record(err != nil || (resp != nil && resp.StatusCode >= 500))
+
+	return resp, err
+}
+
+func (cb *circuitBreaker) record(failure bool) {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+
+	now := time.Now()
+	cb.history = append(cb.history, outcome{at: now, failure: failure})
+
+	cutoff := now.Add(-cb.cfg.Window)
+
+	i := 0
+	for ; i < len(cb.history); i++ {
+		if cb.history[i].at.After(cutoff) {
+			break
+		}
+	}
+	cb.history = cb.history[i:]
+
+	if len(cb.history) < cb.cfg.MinRequests {
+		return
+	}
+
+	var failures int
+	for _, o := range cb.history {
+		if o.failure {
+			failures++
+		}
+	}
+
+	if float64(failures)/float64(len(cb.history)) > cb.cfg.ErrorRateThreshold {
+		cb.openUntil = now.Add(cb.cfg.OpenDuration)
+		cb.history = nil
+	}
+}
@@ -0,0 +1,47 @@
+package phttpmw
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+)
+
+// TestCircuitBreakerOpensAndRecovers drives a CircuitBreaker from closed, to
+// open once the error rate crosses ErrorRateThreshold, to closed again once
+// OpenDuration has elapsed.
+func TestCircuitBreakerOpensAndRecovers(t *testing.T) {
+	next := requesterFunc(func(req *http.Request) (*http.Response, error) {
+		return nil, errors.New("boom")
+	})
+
+	cb := CircuitBreaker(CircuitBreakerConfig{
+		Window:             time.Minute,
+		MinRequests:        2,
+		ErrorRateThreshold: 0.5,
+		OpenDuration:       20 * time.Millisecond,
+	})(next)
+
+	req, err := http.NewRequest(http.MethodGet, "http://example.invalid", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := cb.Do(req); err == nil || errors.Is(err, ErrCircuitOpen) {
+		t.Fatalf("expected the first failure to pass through, got %v", err)
+	}
+
+	if _, err := cb.Do(req); err == nil || errors.Is(err, ErrCircuitOpen) {
+		t.Fatalf("expected the second failure to pass through and trip the breaker, got %v", err)
+	}
+
+	if _, err := cb.Do(req); !errors.Is(err, ErrCircuitOpen) {
+		t.Fatalf("expected the breaker to be open, got %v", err)
+	}
+
+	time.Sleep(30 * time.Millisecond)
+
+	if _, err := cb.Do(req); errors.Is(err, ErrCircuitOpen) {
+		t.Fatalf("expected the breaker to have closed again after OpenDuration, got %v", err)
+	}
+}
@@ -0,0 +1,100 @@
+package phttpmw
+
+import (
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/Deichindianer/phttp"
+)
+
+// redactedHeaders lists header names, matched case-insensitively, whose
+// values are masked before logging.
+var redactedHeaders = map[string]struct{}{
+	"authorization": {},
+}
+
+// redactedQueryParams lists query parameter names, matched case-
+// insensitively, whose values are masked before logging a request URL.
+var redactedQueryParams = map[string]struct{}{
+	"access_token": {},
+	"api_key":      {},
+	"apikey":       {},
+	"key":          {},
+	"password":     {},
+	"secret":       {},
+	"token":        {},
+}
+
+// Logger returns a RequesterMiddleware that logs method, URL, duration and
+// outcome for every attempt using logger. Header values in redactedHeaders
+// (Authorization by default) and query parameter values in
+// redactedQueryParams are replaced with "REDACTED"; any other query
+// parameter is logged as-is. Neither list can be changed from outside this
+// package.
+func Logger(logger *slog.Logger) phttp.RequesterMiddleware {
+	return func(next phttp.Requester) phttp.Requester {
+		return requesterFunc(func(req *http.Request) (*http.Response, error) {
+			start := time.Now()
+
+			resp, err := next.Do(req)
+
+			attrs := []any{
+				slog.String("method", req.Method),
+				slog.String("url", redactURL(req.URL)),
+				slog.Duration("duration", time.Since(start)),
+				slog.Any("headers", redactHeaders(req.Header)),
+			}
+
+			if err != nil {
+				logger.Error("phttp request failed", append(attrs, slog.Any("error", err))...)
+				return resp, err
+			}
+
+			logger.Info("phttp request", append(attrs, slog.Int("status", resp.StatusCode))...)
+
+			return resp, nil
+		})
+	}
+}
+
+// redactURL returns u's string form with any query parameter in
+// redactedQueryParams masked.
+func redactURL(u *url.URL) string {
+	q := u.Query()
+
+	var redacted bool
+
+	for k := range q {
+		if _, ok := redactedQueryParams[strings.ToLower(k)]; ok {
+			q.Set(k, "REDACTED")
+			redacted = true
+		}
+	}
+
+	if !redacted {
+		return u.String()
+	}
+
+	clone := *u
+	clone.RawQuery = q.Encode()
+
+	return clone.String()
+}
+
+func redactHeaders(h http.Header) map[string][]string {
+	redacted := make(map[string][]string, len(h))
+
+	for k, v := range h {
+		if _, ok := redactedHeaders[strings.ToLower(k)]; ok {
+			redacted[k] = []string{"REDACTED"}
+			continue
+		}
+
+		redacted[k] = v
+	}
+
+	return redacted
+}
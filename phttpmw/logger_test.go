@@ -0,0 +1,47 @@
+package phttpmw
+
+import (
+	"bytes"
+	"log/slog"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+// TestLoggerRedactsAuthorizationHeader guards the security-sensitive
+// behavior Logger exists for: neither the Authorization header value nor a
+// token query parameter may reach the log record of a successful attempt.
+func TestLoggerRedactsAuthorizationHeader(t *testing.T) {
+	const secret = "Bearer super-secret-token"
+
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewTextHandler(&buf, nil))
+
+	next := requesterFunc(func(req *http.Request) (*http.Response, error) {
+		return &http.Response{StatusCode: http.StatusOK, Body: http.NoBody}, nil
+	})
+
+	mw := Logger(logger)(next)
+
+	req, err := http.NewRequest(http.MethodGet, "http://example.invalid/?token=also-secret", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Header.Set("Authorization", secret)
+
+	if _, err := mw.Do(req); err != nil {
+		t.Fatal(err)
+	}
+
+	if strings.Contains(buf.String(), secret) {
+		t.Fatalf("expected Authorization header value to be redacted, log was: %s", buf.String())
+	}
+
+	if strings.Contains(buf.String(), "also-secret") {
+		t.Fatalf("expected the token query parameter to be redacted, log was: %s", buf.String())
+	}
+
+	if !strings.Contains(buf.String(), "REDACTED") {
+		t.Fatalf("expected a REDACTED marker in the log, got: %s", buf.String())
+	}
+}
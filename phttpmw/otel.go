@@ -0,0 +1,46 @@
+package phttpmw
+
+import (
+	"net/http"
+
+	"github.com/Deichindianer/phttp"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+)
+
+// OTel returns a RequesterMiddleware that starts a "phttp.attempt" span for
+// every individual attempt, tagging it with http.retry_count - the attempt
+// number phttp.AttemptFromContext reports for the request currently in
+// flight, not a count shared across every request the Client ever makes.
+// tracerName is passed to the global TracerProvider to obtain the tracer.
+func OTel(tracerName string) phttp.RequesterMiddleware {
+	tracer := otel.Tracer(tracerName)
+
+	return func(next phttp.Requester) phttp.Requester {
+		return requesterFunc(func(req *http.Request) (*http.Response, error) {
+			attempt, _ := phttp.AttemptFromContext(req.Context())
+
+			ctx, span := tracer.Start(req.Context(), "phttp.attempt")
+			defer span.End()
+
+			span.SetAttributes(
+				attribute.String("http.method", req.Method),
+				attribute.String("http.url", req.URL.String()),
+				attribute.Int("http.retry_count", attempt),
+			)
+
+			resp, err := next.Do(req.WithContext(ctx))
+			if err != nil {
+				span.RecordError(err)
+				span.SetStatus(codes.Error, err.Error())
+
+				return resp, err
+			}
+
+			span.SetAttributes(attribute.Int("http.status_code", resp.StatusCode))
+
+			return resp, nil
+		})
+	}
+}
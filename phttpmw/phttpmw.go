@@ -0,0 +1,13 @@
+// Package phttpmw provides built-in phttp.RequesterMiddleware implementations:
+// structured logging, OpenTelemetry tracing, Prometheus metrics, and a
+// circuit breaker.
+package phttpmw
+
+import "net/http"
+
+// requesterFunc adapts a plain function to the phttp.Requester interface.
+type requesterFunc func(req *http.Request) (*http.Response, error)
+
+func (f requesterFunc) Do(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
@@ -0,0 +1,42 @@
+package phttpmw
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/Deichindianer/phttp"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// NewAttemptHistogram builds the HistogramVec expected by Prometheus,
+// labeled by method, status and attempt number.
+func NewAttemptHistogram(opts prometheus.HistogramOpts) *prometheus.HistogramVec {
+	return prometheus.NewHistogramVec(opts, []string{"method", "status", "attempt"})
+}
+
+// Prometheus returns a RequesterMiddleware that observes attempt latency on
+// histogram, labeled by method, status ("error" on a transport failure) and
+// attempt number - the attempt phttp.AttemptFromContext reports for the
+// request currently in flight, not a count shared across every request the
+// Client ever makes. Register histogram with a prometheus.Registerer before use.
+func Prometheus(histogram *prometheus.HistogramVec) phttp.RequesterMiddleware {
+	return func(next phttp.Requester) phttp.Requester {
+		return requesterFunc(func(req *http.Request) (*http.Response, error) {
+			attempt, _ := phttp.AttemptFromContext(req.Context())
+			start := time.Now()
+
+			resp, err := next.Do(req)
+
+			status := "error"
+			if err == nil {
+				status = strconv.Itoa(resp.StatusCode)
+			}
+
+			histogram.WithLabelValues(req.Method, status, strconv.Itoa(attempt)).
+				Observe(time.Since(start).Seconds())
+
+			return resp, err
+		})
+	}
+}